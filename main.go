@@ -0,0 +1,195 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	kcollectors "k8s.io/kube-state-metrics/pkg/collectors"
+	"k8s.io/kube-state-metrics/pkg/metric"
+	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/whiteblacklist"
+)
+
+const (
+	contentTypeText        = "text/plain; version=0.0.4"
+	contentTypeOpenMetrics = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+)
+
+// negotiateFormat picks the OpenMetrics text format when the client's
+// Accept header asks for it and falls back to kube-state-metrics' classic
+// Prometheus text format otherwise - the format everything that doesn't
+// speak OpenMetrics already understands.
+func negotiateFormat(accept string) string {
+	if strings.Contains(accept, "application/openmetrics-text") {
+		return "openmetrics"
+	}
+	return "text"
+}
+
+// metricHandler renders every enabled collector's metrics as an HTTP
+// response, gzip-compressing the body when the caller asked for it and the
+// client advertises support for it. Families are streamed straight to the
+// response as they're rendered rather than buffered into one big string, so
+// memory use stays flat regardless of how many series a scrape produces;
+// that leaves the response's length unknown up front, so it goes out
+// chunked instead of with a Content-Length.
+type metricHandler struct {
+	collectors         []*kcollectors.Collector
+	enableGZIPEncoding bool
+
+	mu              sync.Mutex
+	scrapesByFormat map[string]float64
+}
+
+func newMetricHandler(collectors []*kcollectors.Collector, enableGZIPEncoding bool) *metricHandler {
+	return &metricHandler{
+		collectors:         collectors,
+		enableGZIPEncoding: enableGZIPEncoding,
+		scrapesByFormat:    map[string]float64{},
+	}
+}
+
+// recordScrape counts this scrape against format and returns a Family
+// exposing every format's running total so far, so operators can see which
+// exposition format clients are actually requesting.
+func (m *metricHandler) recordScrape(format string) metric.Family {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.scrapesByFormat[format]++
+
+	family := metric.Family{
+		Name: "kube_state_metrics_scrape_format_total",
+		Help: "Number of /metrics scrapes served, by negotiated exposition format.",
+	}
+	for f, count := range m.scrapesByFormat {
+		family.Metrics = append(family.Metrics, &metric.Metric{
+			LabelKeys:   []string{"format"},
+			LabelValues: []string{f},
+			Value:       count,
+		})
+	}
+	return family
+}
+
+func (m *metricHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	format := negotiateFormat(r.Header.Get("Accept"))
+	scrapeFormatFamily := m.recordScrape(format)
+
+	contentType := contentTypeText
+	if format == "openmetrics" {
+		contentType = contentTypeOpenMetrics
+	}
+	w.Header().Set("Content-Type", contentType)
+
+	var out io.Writer = w
+	var gz *gzip.Writer
+	if m.enableGZIPEncoding && strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+		w.Header().Set("Content-Encoding", "gzip")
+		gz = gzip.NewWriter(w)
+		defer gz.Close()
+		out = gz
+	}
+
+	flusher, canFlush := w.(http.Flusher)
+
+	writeFamily := func(family metric.Family) {
+		if format == "openmetrics" {
+			family.WriteOpenMetrics(out)
+		} else {
+			family.Write(out)
+		}
+		if gz != nil {
+			gz.Flush()
+		}
+		if canFlush {
+			flusher.Flush()
+		}
+	}
+
+	for _, c := range m.collectors {
+		for _, family := range c.Collect() {
+			writeFamily(family)
+		}
+	}
+	writeFamily(scrapeFormatFamily)
+
+	if format == "openmetrics" {
+		fmt.Fprint(out, "# EOF\n")
+	}
+}
+
+func main() {
+	opts := options.NewOptions()
+	opts.AddFlags()
+	if err := opts.Parse(os.Args[1:]); err != nil {
+		log.Fatalf("error parsing flags: %v", err)
+	}
+
+	if opts.Help {
+		opts.Usage()
+		return
+	}
+
+	config, err := clientcmd.BuildConfigFromFlags(opts.Apiserver, opts.Kubeconfig)
+	if err != nil {
+		log.Fatalf("error building kubeconfig: %v", err)
+	}
+
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		log.Fatalf("error building kubernetes clientset: %v", err)
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		log.Fatalf("error building dynamic clientset: %v", err)
+	}
+
+	l, err := whiteblacklist.New(map[string]struct{}{}, map[string]struct{}{})
+	if err != nil {
+		log.Fatalf("error initializing metric white/blacklist: %v", err)
+	}
+
+	builder := kcollectors.NewBuilder(context.Background(), opts)
+	builder.WithEnabledCollectors(opts.Collectors)
+	builder.WithKubeClient(kubeClient)
+	builder.WithDynamicClient(dynamicClient)
+	builder.WithNamespaces(opts.Namespaces)
+	builder.WithWhiteBlackList(l)
+
+	collectors, err := builder.Build()
+	if err != nil {
+		log.Fatalf("error building collectors: %v", err)
+	}
+
+	http.Handle("/metrics", newMetricHandler(collectors, true))
+	log.Fatal(http.ListenAndServe(fmt.Sprintf("%v:%v", opts.Host, opts.Port), nil))
+}
@@ -18,8 +18,10 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"io/ioutil"
 	"net/http/httptest"
+	"os"
 	"strconv"
 	"strings"
 	"testing"
@@ -29,6 +31,10 @@ import (
 
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/kubernetes/fake"
 	kcollectors "k8s.io/kube-state-metrics/pkg/collectors"
 	"k8s.io/kube-state-metrics/pkg/whiteblacklist"
@@ -67,13 +73,17 @@ func BenchmarkKubeStateMetrics(b *testing.B) {
 	// This test is not suitable to be compared in terms of time, as it includes
 	// a one second wait. Use for memory allocation comparisons, profiling, ...
 	b.Run("GenerateMetrics", func(b *testing.B) {
-		collectors = builder.Build()
+		var err error
+		collectors, err = builder.Build()
+		if err != nil {
+			b.Fatal(err)
+		}
 
 		// Wait for caches to fill
 		time.Sleep(time.Second)
 	})
 
-	handler := metricHandler{collectors, false}
+	handler := newMetricHandler(collectors, false)
 	req := httptest.NewRequest("GET", "http://localhost:8080/metrics", nil)
 
 	b.Run("MakeRequests", func(b *testing.B) {
@@ -88,14 +98,163 @@ func BenchmarkKubeStateMetrics(b *testing.B) {
 				b.Fatalf("expected 200 status code but got %v", resp.StatusCode)
 			}
 
-			if resp.ContentLength == -1 {
-				b.Fatal("expected content length of response not to be unknown")
+			// Families are streamed rather than buffered, so the response
+			// goes out chunked and ContentLength is unknown (-1) by
+			// design; measure what was actually written instead.
+			if resp.ContentLength != -1 {
+				b.Fatalf("expected content length of response to be unknown (chunked), got %v", resp.ContentLength)
+			}
+			body, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				b.Fatal(err)
 			}
-			accumulatedContentLength += resp.ContentLength
+			accumulatedContentLength += int64(len(body))
 		}
 
 		b.SetBytes(accumulatedContentLength)
 	})
+
+	// kube_pod_service joins fixtureMultiplier pods against
+	// fixtureMultiplier services by selector. The per-namespace inverted
+	// selector index computes those join rows from informer events, so
+	// collecting here only lists the precomputed join store instead of
+	// matching every pod against every service on each scrape.
+	b.Run("JoinIndexScale", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, c := range collectors {
+				c.Collect()
+			}
+		}
+	})
+}
+
+// BenchmarkCustomResourceCollector exercises the --custom-resource-config
+// path with a sample config naming two CRDs and fixtureMultiplier objects
+// of each, so the dynamic-informer/JSONPath path's memory and latency cost
+// can be compared directly against BenchmarkKubeStateMetrics' built-in
+// collectors.
+func BenchmarkCustomResourceCollector(b *testing.B) {
+	fixtureMultiplier := 1000
+
+	widgetGVR := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+	gadgetGVR := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "gadgets"}
+
+	configFile, err := ioutil.TempFile("", "custom-resource-config-*.yaml")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer os.Remove(configFile.Name())
+
+	configContent := `resources:
+  - group: example.com
+    version: v1
+    resource: widgets
+    metrics:
+      - name: kube_customresource_widget_replicas
+        help: Replicas requested by a widget custom resource.
+        valuePath: '{.spec.replicas}'
+        labels:
+          - name: namespace
+            path: '{.metadata.namespace}'
+          - name: widget
+            path: '{.metadata.name}'
+  - group: example.com
+    version: v1
+    resource: gadgets
+    metrics:
+      - name: kube_customresource_gadget_replicas
+        help: Replicas requested by a gadget custom resource.
+        valuePath: '{.spec.replicas}'
+        labels:
+          - name: namespace
+            path: '{.metadata.namespace}'
+          - name: gadget
+            path: '{.metadata.name}'
+`
+	if _, err := configFile.WriteString(configContent); err != nil {
+		b.Fatal(err)
+	}
+	if err := configFile.Close(); err != nil {
+		b.Fatal(err)
+	}
+
+	objects := make([]runtime.Object, 0, fixtureMultiplier*2)
+	for i := 0; i < fixtureMultiplier; i++ {
+		objects = append(objects, customResourceFixture(widgetGVR, "widget", i))
+		objects = append(objects, customResourceFixture(gadgetGVR, "gadget", i))
+	}
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		widgetGVR: "WidgetList",
+		gadgetGVR: "GadgetList",
+	}, objects...)
+
+	opts := options.NewOptions()
+	opts.CustomResourceConfigFile = configFile.Name()
+
+	builder := kcollectors.NewBuilder(context.TODO(), opts)
+	builder.WithEnabledCollectors(options.CollectorSet{})
+	builder.WithDynamicClient(dynamicClient)
+	builder.WithNamespaces(options.DefaultNamespaces)
+
+	l, err := whiteblacklist.New(map[string]struct{}{}, map[string]struct{}{})
+	if err != nil {
+		b.Fatal(err)
+	}
+	builder.WithWhiteBlackList(l)
+
+	var collectors []*kcollectors.Collector
+	b.Run("GenerateMetrics", func(b *testing.B) {
+		var err error
+		collectors, err = builder.Build()
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		// Wait for caches to fill
+		time.Sleep(time.Second)
+	})
+
+	handler := newMetricHandler(collectors, false)
+	req := httptest.NewRequest("GET", "http://localhost:8080/metrics", nil)
+
+	b.Run("MakeRequests", func(b *testing.B) {
+		var accumulatedContentLength int64
+
+		for i := 0; i < 1000; i++ {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			resp := w.Result()
+			if resp.StatusCode != 200 {
+				b.Fatalf("expected 200 status code but got %v", resp.StatusCode)
+			}
+
+			body, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				b.Fatal(err)
+			}
+			accumulatedContentLength += int64(len(body))
+		}
+
+		b.SetBytes(accumulatedContentLength)
+	})
+}
+
+func customResourceFixture(gvr schema.GroupVersionResource, kindPrefix string, index int) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": gvr.GroupVersion().String(),
+			"kind":       kindPrefix,
+			"metadata": map[string]interface{}{
+				"name":      fmt.Sprintf("%s%d", kindPrefix, index),
+				"namespace": metav1.NamespaceDefault,
+			},
+			"spec": map[string]interface{}{
+				"replicas": int64(index % 5),
+			},
+		},
+	}
 }
 
 // TestFullScrapeCycle is a simple smoke test covering the entire cycle from
@@ -123,12 +282,15 @@ func TestFullScrapeCycle(t *testing.T) {
 	}
 	builder.WithWhiteBlackList(l)
 
-	collectors := builder.Build()
+	collectors, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	// Wait for caches to fill
 	time.Sleep(time.Second)
 
-	handler := metricHandler{collectors, false}
+	handler := newMetricHandler(collectors, false)
 	req := httptest.NewRequest("GET", "http://localhost:8080/metrics", nil)
 
 	w := httptest.NewRecorder()
@@ -151,6 +313,8 @@ func TestFullScrapeCycle(t *testing.T) {
 # HELP kube_pod_status_phase The pods current phase.
 # HELP kube_pod_status_ready Describes whether the pod is ready to serve requests.
 # HELP kube_pod_status_scheduled Describes the status of the scheduling process for the pod.
+# HELP kube_pod_status_condition The pod's current status of each of its conditions, with any additional reason for it.
+# HELP kube_pod_status_condition_last_transition_time Unix timestamp of the last transition of a pod's condition
 # HELP kube_pod_container_info Information about a container in a pod.
 # HELP kube_pod_container_status_waiting Describes whether the container is currently in waiting state.
 # HELP kube_pod_container_status_waiting_reason Describes the reason the container is currently in waiting state.
@@ -168,6 +332,21 @@ func TestFullScrapeCycle(t *testing.T) {
 # HELP kube_pod_container_resource_limits_memory_bytes The limit on memory to be used by a container in bytes.
 # HELP kube_pod_spec_volumes_persistentvolumeclaims_info Information about persistentvolumeclaim volumes in a pod.
 # HELP kube_pod_spec_volumes_persistentvolumeclaims_readonly Describes whether a persistentvolumeclaim is mounted read only.
+# HELP kube_pod_init_container_info Information about an init container in a pod.
+# HELP kube_pod_init_container_status_waiting Describes whether the init container is currently in waiting state.
+# HELP kube_pod_init_container_status_waiting_reason Describes the reason the init container is currently in waiting state.
+# HELP kube_pod_init_container_status_running Describes whether the init container is currently in running state.
+# HELP kube_pod_init_container_status_terminated Describes whether the init container is currently in terminated state.
+# HELP kube_pod_init_container_status_terminated_reason Describes the reason the init container is currently in terminated state.
+# HELP kube_pod_init_container_status_last_terminated_reason Describes the last reason the init container was in terminated state.
+# HELP kube_pod_init_container_status_ready Describes whether the init containers readiness check succeeded.
+# HELP kube_pod_init_container_status_restarts_total The number of init container restarts per container.
+# HELP kube_pod_init_container_resource_requests The number of requested request resource by an init container.
+# HELP kube_pod_init_container_resource_limits The number of requested limit resource by an init container.
+# HELP kube_pod_init_container_resource_requests_cpu_cores The number of requested cpu cores by an init container.
+# HELP kube_pod_init_container_resource_requests_memory_bytes The number of requested memory bytes by an init container.
+# HELP kube_pod_init_container_resource_limits_cpu_cores The limit on cpu cores to be used by an init container.
+# HELP kube_pod_init_container_resource_limits_memory_bytes The limit on memory to be used by an init container in bytes.
 # HELP kube_service_info Information about service.
 kube_service_info{namespace="default",service="service0",cluster_ip="",external_name="",load_balancer_ip=""} 1
 # HELP kube_service_created Unix creation timestamp
@@ -176,7 +355,10 @@ kube_service_spec_type{namespace="default",service="service0",type=""} 1
 # HELP kube_service_labels Kubernetes labels converted to Prometheus labels.
 kube_service_labels{namespace="default",service="service0"} 1
 # HELP kube_service_spec_external_ip Service external ips. One series for each ip
-# HELP kube_service_status_load_balancer_ingress Service load balancer ingress status`
+# HELP kube_service_status_load_balancer_ingress Service load balancer ingress status
+# HELP kube_pod_service Service selecting a pod, for joining pod and service metrics without a separate discovery layer.
+# HELP kube_state_metrics_scrape_format_total Number of /metrics scrapes served, by negotiated exposition format.
+kube_state_metrics_scrape_format_total{format="text"} 1`
 
 	got := strings.TrimSpace(string(body))
 
@@ -185,6 +367,152 @@ kube_service_labels{namespace="default",service="service0"} 1
 	}
 }
 
+// TestFullScrapeCycleOpenMetrics is TestFullScrapeCycle's counterpart for a
+// client that asks for the OpenMetrics text format: it checks the
+// negotiated Content-Type, the "# TYPE" line OpenMetrics requires ahead of
+// every "# HELP", the closing "# EOF" trailer, and that the scrape got
+// counted against the "openmetrics" format rather than "text".
+func TestFullScrapeCycleOpenMetrics(t *testing.T) {
+	t.Parallel()
+
+	kubeClient := fake.NewSimpleClientset()
+
+	err := service(kubeClient, 0)
+	if err != nil {
+		t.Fatalf("failed to insert sample service %v", err.Error())
+	}
+
+	opts := options.NewOptions()
+
+	builder := kcollectors.NewBuilder(context.TODO(), opts)
+	builder.WithEnabledCollectors(options.DefaultCollectors)
+	builder.WithKubeClient(kubeClient)
+	builder.WithNamespaces(options.DefaultNamespaces)
+
+	l, err := whiteblacklist.New(map[string]struct{}{}, map[string]struct{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder.WithWhiteBlackList(l)
+
+	collectors, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Wait for caches to fill
+	time.Sleep(time.Second)
+
+	handler := newMetricHandler(collectors, false)
+	req := httptest.NewRequest("GET", "http://localhost:8080/metrics", nil)
+	req.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200 status code but got %v", resp.StatusCode)
+	}
+
+	if ct := resp.Header.Get("Content-Type"); ct != contentTypeOpenMetrics {
+		t.Fatalf("expected Content-Type %q, got %q", contentTypeOpenMetrics, ct)
+	}
+
+	body, _ := ioutil.ReadAll(resp.Body)
+	got := strings.TrimSpace(string(body))
+
+	wantLines := []string{
+		"# TYPE kube_service_info gauge",
+		"# HELP kube_service_info Information about service.",
+		`kube_service_info{namespace="default",service="service0",cluster_ip="",external_name="",load_balancer_ip=""} 1`,
+		"# TYPE kube_state_metrics_scrape_format_total gauge",
+		`kube_state_metrics_scrape_format_total{format="openmetrics"} 1`,
+	}
+	for _, want := range wantLines {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected body to contain:\n%v\nbut got:\n%v", want, got)
+		}
+	}
+
+	if !strings.HasSuffix(got, "# EOF") {
+		t.Fatalf("expected body to end with the OpenMetrics # EOF trailer, got:\n%v", got)
+	}
+}
+
+// TestPodStatusConditionReasons checks that kube_pod_status_condition passes
+// a condition's reason straight through as a label value, for every reason
+// the kubelet/scheduler are known to set on a False/Unknown condition, not
+// just the one the other fixtures happen to exercise.
+func TestPodStatusConditionReasons(t *testing.T) {
+	t.Parallel()
+
+	reasons := []string{
+		"ContainersNotReady",
+		"PodCompleted",
+		"ContainersNotInitialized",
+		"UnknownContainerStatuses",
+	}
+
+	kubeClient := fake.NewSimpleClientset()
+	for i, reason := range reasons {
+		p := v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "pod" + strconv.Itoa(i),
+			},
+			Status: v1.PodStatus{
+				Conditions: []v1.PodCondition{
+					{
+						Type:   v1.PodReady,
+						Status: v1.ConditionFalse,
+						Reason: reason,
+					},
+				},
+			},
+		}
+		if _, err := kubeClient.CoreV1().Pods(metav1.NamespaceDefault).Create(&p); err != nil {
+			t.Fatalf("failed to insert sample pod %v", err.Error())
+		}
+	}
+
+	opts := options.NewOptions()
+
+	builder := kcollectors.NewBuilder(context.TODO(), opts)
+	builder.WithEnabledCollectors(options.CollectorSet{"pods": struct{}{}})
+	builder.WithKubeClient(kubeClient)
+	builder.WithNamespaces(options.DefaultNamespaces)
+
+	l, err := whiteblacklist.New(map[string]struct{}{}, map[string]struct{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder.WithWhiteBlackList(l)
+
+	collectors, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Wait for caches to fill
+	time.Sleep(time.Second)
+
+	handler := newMetricHandler(collectors, false)
+	req := httptest.NewRequest("GET", "http://localhost:8080/metrics", nil)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	body, _ := ioutil.ReadAll(w.Result().Body)
+	got := string(body)
+
+	for i, reason := range reasons {
+		want := fmt.Sprintf(`kube_pod_status_condition{namespace="default",pod="pod%d",condition="Ready",status="False",reason="%s"} 1`, i, reason)
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected body to contain:\n%v\nbut got:\n%v", want, got)
+		}
+	}
+}
+
 func injectFixtures(client *fake.Clientset, multiplier int) error {
 	creators := []func(*fake.Clientset, int) error{
 		configMap,
@@ -226,6 +554,9 @@ func service(client *fake.Clientset, index int) error {
 			Name:            "service" + i,
 			ResourceVersion: "123456",
 		},
+		Spec: v1.ServiceSpec{
+			Selector: map[string]string{"app": "app" + i},
+		},
 	}
 	_, err := client.CoreV1().Services(metav1.NamespaceDefault).Create(&service)
 	return err
@@ -236,9 +567,29 @@ func pod(client *fake.Clientset, index int) error {
 
 	pod := v1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: "pod" + i,
+			Name:   "pod" + i,
+			Labels: map[string]string{"app": "app" + i},
+		},
+		Spec: v1.PodSpec{
+			InitContainers: []v1.Container{
+				v1.Container{
+					Name:  "initcontainer1",
+					Image: "k8s.gcr.io/hyperkube1",
+				},
+			},
 		},
 		Status: v1.PodStatus{
+			Conditions: []v1.PodCondition{
+				v1.PodCondition{
+					Type:   v1.PodScheduled,
+					Status: v1.ConditionTrue,
+				},
+				v1.PodCondition{
+					Type:   v1.PodReady,
+					Status: v1.ConditionFalse,
+					Reason: "ContainersNotReady",
+				},
+			},
 			ContainerStatuses: []v1.ContainerStatus{
 				v1.ContainerStatus{
 					Name:        "container1",
@@ -247,9 +598,17 @@ func pod(client *fake.Clientset, index int) error {
 					ContainerID: "docker://ab123",
 				},
 			},
+			InitContainerStatuses: []v1.ContainerStatus{
+				v1.ContainerStatus{
+					Name:        "initcontainer1",
+					Image:       "k8s.gcr.io/hyperkube1",
+					ImageID:     "docker://sha256:bbb",
+					ContainerID: "docker://cd456",
+				},
+			},
 		},
 	}
 
 	_, err := client.CoreV1().Pods(metav1.NamespaceDefault).Create(&pod)
 	return err
-}
\ No newline at end of file
+}
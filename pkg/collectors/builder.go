@@ -0,0 +1,146 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"context"
+	"sort"
+
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/whiteblacklist"
+)
+
+// availableCollectors maps a collector name, as accepted by
+// --collectors/options.DefaultCollectors, to the function that builds it.
+var availableCollectors = map[string]func(*Builder) *Collector{
+	"configmaps": func(b *Builder) *Collector { return b.buildConfigMapCollector() },
+	"pods":       func(b *Builder) *Collector { return b.buildPodCollector() },
+	"services":   func(b *Builder) *Collector { return b.buildServiceCollector() },
+}
+
+// Builder assembles the Collectors enabled for a scrape from a Kubernetes
+// client, the set of namespaces to watch and the enabled collector names.
+type Builder struct {
+	ctx            context.Context
+	kubeClient     kubernetes.Interface
+	dynamicClient  dynamic.Interface
+	namespaces     options.NamespaceList
+	whiteBlackList *whiteblacklist.WhiteBlackList
+	enabled        []string
+
+	// customResourceConfigFile is the --custom-resource-config path, if
+	// any; Build consults it through buildCustomResourceCollectors.
+	customResourceConfigFile string
+
+	// joinIndex is set by Build() whenever both "pods" and "services" are
+	// enabled, and stays set afterwards, letting their informer event
+	// handlers feed the kube_pod_service join collector.
+	joinIndex *podServiceIndex
+}
+
+// NewBuilder returns an empty Builder seeded with opts' static settings.
+func NewBuilder(ctx context.Context, opts *options.Options) *Builder {
+	return &Builder{ctx: ctx, customResourceConfigFile: opts.CustomResourceConfigFile}
+}
+
+// WithEnabledCollectors records which collectors Build should construct, in
+// a deterministic (alphabetical) order so the exposed families always come
+// out in the same sequence regardless of map iteration order.
+func (b *Builder) WithEnabledCollectors(c options.CollectorSet) *Builder {
+	b.enabled = b.enabled[:0]
+	for name := range c {
+		b.enabled = append(b.enabled, name)
+	}
+	sort.Strings(b.enabled)
+	return b
+}
+
+// WithKubeClient sets the client used to list and watch resources.
+func (b *Builder) WithKubeClient(client kubernetes.Interface) *Builder {
+	b.kubeClient = client
+	return b
+}
+
+// WithNamespaces restricts the collectors to the given namespaces.
+func (b *Builder) WithNamespaces(namespaces options.NamespaceList) *Builder {
+	b.namespaces = namespaces
+	return b
+}
+
+// WithDynamicClient sets the client used to inform on the CRDs
+// --custom-resource-config configures. It is unused when no config file is
+// set.
+func (b *Builder) WithDynamicClient(client dynamic.Interface) *Builder {
+	b.dynamicClient = client
+	return b
+}
+
+// WithWhiteBlackList sets the metric name filter applied to generated
+// families.
+func (b *Builder) WithWhiteBlackList(l *whiteblacklist.WhiteBlackList) *Builder {
+	b.whiteBlackList = l
+	return b
+}
+
+// Build constructs one Collector per enabled collector name, starting the
+// informer backing each of their stores. When both "pods" and "services"
+// are enabled it additionally builds the kube_pod_service join collector,
+// fed by those two informers' events rather than its own. When
+// --custom-resource-config is set, it also builds one Collector per
+// configured CRD; a config file that cannot be read or parsed, or that holds
+// an invalid JSONPath expression, is the only way Build can fail.
+func (b *Builder) Build() ([]*Collector, error) {
+	podsEnabled, servicesEnabled := false, false
+	for _, name := range b.enabled {
+		switch name {
+		case "pods":
+			podsEnabled = true
+		case "services":
+			servicesEnabled = true
+		}
+	}
+
+	if podsEnabled && servicesEnabled {
+		b.joinIndex = newPodServiceIndex(cache.NewStore(podServiceMatchKeyFunc))
+	}
+
+	collectors := make([]*Collector, 0, len(b.enabled)+1)
+
+	for _, name := range b.enabled {
+		construct, ok := availableCollectors[name]
+		if !ok {
+			continue
+		}
+		collectors = append(collectors, construct(b))
+	}
+
+	if b.joinIndex != nil {
+		collectors = append(collectors, NewCollector(b.joinIndex.store, podServiceMetricFamilies, b.whiteBlackList))
+	}
+
+	customCollectors, err := b.buildCustomResourceCollectors()
+	if err != nil {
+		return nil, err
+	}
+	collectors = append(collectors, customCollectors...)
+
+	return collectors, nil
+}
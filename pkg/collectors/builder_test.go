@@ -0,0 +1,53 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+// TestWithEnabledCollectorsOrderIsDeterministic guards against a regression
+// where b.enabled took on Go's randomized map iteration order, making the
+// collectors Build produces - and so the families a scrape exposes them in -
+// change from run to run even though the same set was enabled every time.
+func TestWithEnabledCollectorsOrderIsDeterministic(t *testing.T) {
+	set := options.CollectorSet{
+		"services":   struct{}{},
+		"pods":       struct{}{},
+		"configmaps": struct{}{},
+	}
+
+	var first []string
+	for i := 0; i < 10; i++ {
+		b := (&Builder{}).WithEnabledCollectors(set)
+		if first == nil {
+			first = append([]string{}, b.enabled...)
+			continue
+		}
+		if !reflect.DeepEqual(first, b.enabled) {
+			t.Fatalf("enabled collector order changed between runs: %v != %v", first, b.enabled)
+		}
+	}
+
+	want := []string{"configmaps", "pods", "services"}
+	if !reflect.DeepEqual(first, want) {
+		t.Fatalf("expected alphabetical order %v, got %v", want, first)
+	}
+}
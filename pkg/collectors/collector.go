@@ -0,0 +1,79 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"k8s.io/client-go/tools/cache"
+
+	"k8s.io/kube-state-metrics/pkg/metric"
+	"k8s.io/kube-state-metrics/pkg/whiteblacklist"
+)
+
+// FamilyGenerator turns a single cached Kubernetes object into the metric
+// Families describing it. The Name and Help are static so that every
+// collector always advertises its full set of series, even when the cache
+// is empty or no object produces a given metric. Type is the OpenMetrics
+// type of the generated family; leaving it empty advertises "gauge", which
+// is what every built-in generator produces.
+type FamilyGenerator struct {
+	Name         string
+	Help         string
+	Type         string
+	GenerateFunc func(obj interface{}) []*metric.Metric
+}
+
+// Collector glues a cache.Store kept up to date by an informer to the
+// ordered list of FamilyGenerators that know how to describe its contents.
+type Collector struct {
+	Store      cache.Store
+	generators []FamilyGenerator
+
+	// whiteBlackList is consulted by name for every generator, built-in or
+	// custom resource alike, so the metric whitelist/blacklist covers
+	// every family this Collector can produce. It may be nil, in
+	// which case nothing is excluded.
+	whiteBlackList *whiteblacklist.WhiteBlackList
+}
+
+// NewCollector returns a Collector that will run every generator over the
+// contents of store whenever it is collected, skipping any generator whose
+// name whiteBlackList excludes.
+func NewCollector(store cache.Store, generators []FamilyGenerator, whiteBlackList *whiteblacklist.WhiteBlackList) *Collector {
+	return &Collector{Store: store, generators: generators, whiteBlackList: whiteBlackList}
+}
+
+// Collect runs every registered generator not excluded by whiteBlackList
+// across the current contents of the store and returns one metric.Family
+// per generator, in the order the generators were registered.
+func (c *Collector) Collect() []metric.Family {
+	objects := c.Store.List()
+
+	families := make([]metric.Family, 0, len(c.generators))
+	for _, gen := range c.generators {
+		if c.whiteBlackList != nil && c.whiteBlackList.IsExcluded(gen.Name) {
+			continue
+		}
+
+		family := metric.Family{Name: gen.Name, Help: gen.Help, Type: gen.Type}
+		for _, obj := range objects {
+			family.Metrics = append(family.Metrics, gen.GenerateFunc(obj)...)
+		}
+		families = append(families, family)
+	}
+
+	return families
+}
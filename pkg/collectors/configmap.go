@@ -0,0 +1,78 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+var configMapMetricFamilies = []FamilyGenerator{
+	{
+		Name: "kube_configmap_info",
+		Help: "Information about configmap.",
+		GenerateFunc: wrapConfigMapFunc(func(c *v1.ConfigMap) []*metric.Metric {
+			return []*metric.Metric{{Value: 1}}
+		}),
+	},
+	{
+		Name: "kube_configmap_created",
+		Help: "Unix creation timestamp",
+		GenerateFunc: wrapConfigMapFunc(func(c *v1.ConfigMap) []*metric.Metric {
+			if c.CreationTimestamp.IsZero() {
+				return nil
+			}
+			return []*metric.Metric{{Value: float64(c.CreationTimestamp.Unix())}}
+		}),
+	},
+	{
+		Name: "kube_configmap_metadata_resource_version",
+		Help: "Resource version representing a specific version of the configmap.",
+		GenerateFunc: wrapConfigMapFunc(func(c *v1.ConfigMap) []*metric.Metric {
+			return []*metric.Metric{
+				{
+					LabelKeys:   []string{"resource_version"},
+					LabelValues: []string{c.ResourceVersion},
+					Value:       1,
+				},
+			}
+		}),
+	},
+}
+
+func wrapConfigMapFunc(f func(*v1.ConfigMap) []*metric.Metric) func(interface{}) []*metric.Metric {
+	return func(obj interface{}) []*metric.Metric {
+		cm := obj.(*v1.ConfigMap)
+
+		ms := f(cm)
+		for _, m := range ms {
+			m.LabelKeys = append([]string{"namespace", "configmap"}, m.LabelKeys...)
+			m.LabelValues = append([]string{cm.Namespace, cm.Name}, m.LabelValues...)
+		}
+		return ms
+	}
+}
+
+// buildConfigMapCollector wires a Collector for config maps up to an
+// informer scoped to b.namespaces. kube_configmap_* is not part of
+// options.DefaultCollectors; it only runs when explicitly enabled.
+func (b *Builder) buildConfigMapCollector() *Collector {
+	store := startInformer(b, &v1.ConfigMap{}, "configmaps", cache.ResourceEventHandlerFuncs{})
+	return NewCollector(store, configMapMetricFamilies, b.whiteBlackList)
+}
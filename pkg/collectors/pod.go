@@ -0,0 +1,649 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/tools/cache"
+
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+var podMetricFamilies = []FamilyGenerator{
+	{
+		Name: "kube_pod_info",
+		Help: "Information about pod.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			return []*metric.Metric{
+				{
+					LabelKeys:   []string{"host_ip", "pod_ip", "node", "created_by_kind", "created_by_name"},
+					LabelValues: []string{p.Status.HostIP, p.Status.PodIP, p.Spec.NodeName, ownerKindName(p)[0], ownerKindName(p)[1]},
+					Value:       1,
+				},
+			}
+		}),
+	},
+	{
+		Name: "kube_pod_start_time",
+		Help: "Start time in unix timestamp for a pod.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			if p.Status.StartTime == nil {
+				return nil
+			}
+			return []*metric.Metric{{Value: float64(p.Status.StartTime.Unix())}}
+		}),
+	},
+	{
+		Name: "kube_pod_completion_time",
+		Help: "Completion time in unix timestamp for a pod.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			var latest *v1.ContainerStateTerminated
+			for _, cs := range p.Status.ContainerStatuses {
+				if t := cs.State.Terminated; t != nil && (latest == nil || t.FinishedAt.After(latest.FinishedAt.Time)) {
+					latest = t
+				}
+			}
+			if latest == nil {
+				return nil
+			}
+			return []*metric.Metric{{Value: float64(latest.FinishedAt.Unix())}}
+		}),
+	},
+	{
+		Name: "kube_pod_owner",
+		Help: "Information about the Pod's owner.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			ms := make([]*metric.Metric, 0, len(p.OwnerReferences))
+			for _, o := range p.OwnerReferences {
+				ms = append(ms, &metric.Metric{
+					LabelKeys:   []string{"owner_kind", "owner_name", "owner_is_controller"},
+					LabelValues: []string{o.Kind, o.Name, boolPtrString(o.Controller)},
+					Value:       1,
+				})
+			}
+			return ms
+		}),
+	},
+	{
+		Name: "kube_pod_labels",
+		Help: "Kubernetes labels converted to Prometheus labels.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			keys, values := kubeLabelsToPrometheusLabels(p.Labels)
+			return []*metric.Metric{{LabelKeys: keys, LabelValues: values, Value: 1}}
+		}),
+	},
+	{
+		Name: "kube_pod_created",
+		Help: "Unix creation timestamp",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			if p.CreationTimestamp.IsZero() {
+				return nil
+			}
+			return []*metric.Metric{{Value: float64(p.CreationTimestamp.Unix())}}
+		}),
+	},
+	{
+		Name: "kube_pod_status_scheduled_time",
+		Help: "Unix timestamp when pod moved into scheduled status",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			for _, c := range p.Status.Conditions {
+				if c.Type == v1.PodScheduled && c.Status == v1.ConditionTrue {
+					return []*metric.Metric{{Value: float64(c.LastTransitionTime.Unix())}}
+				}
+			}
+			return nil
+		}),
+	},
+	{
+		Name: "kube_pod_status_phase",
+		Help: "The pods current phase.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			return []*metric.Metric{
+				{
+					LabelKeys:   []string{"phase"},
+					LabelValues: []string{string(p.Status.Phase)},
+					Value:       1,
+				},
+			}
+		}),
+	},
+	{
+		Name: "kube_pod_status_ready",
+		Help: "Describes whether the pod is ready to serve requests.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			return []*metric.Metric{{Value: metric.BoolFloat64(podConditionStatus(p, v1.PodReady))}}
+		}),
+	},
+	{
+		Name: "kube_pod_status_scheduled",
+		Help: "Describes the status of the scheduling process for the pod.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			return []*metric.Metric{{Value: metric.BoolFloat64(podConditionStatus(p, v1.PodScheduled))}}
+		}),
+	},
+	{
+		Name: "kube_pod_status_condition",
+		Help: "The pod's current status of each of its conditions, with any additional reason for it.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			ms := make([]*metric.Metric, 0, len(p.Status.Conditions))
+			for _, c := range p.Status.Conditions {
+				ms = append(ms, &metric.Metric{
+					LabelKeys:   []string{"condition", "status", "reason"},
+					LabelValues: []string{string(c.Type), string(c.Status), c.Reason},
+					Value:       1,
+				})
+			}
+			return ms
+		}),
+	},
+	{
+		Name: "kube_pod_status_condition_last_transition_time",
+		Help: "Unix timestamp of the last transition of a pod's condition",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			ms := make([]*metric.Metric, 0, len(p.Status.Conditions))
+			for _, c := range p.Status.Conditions {
+				if c.LastTransitionTime.IsZero() {
+					continue
+				}
+				ms = append(ms, &metric.Metric{
+					LabelKeys:   []string{"condition", "status"},
+					LabelValues: []string{string(c.Type), string(c.Status)},
+					Value:       float64(c.LastTransitionTime.Unix()),
+				})
+			}
+			return ms
+		}),
+	},
+	{
+		Name: "kube_pod_container_info",
+		Help: "Information about a container in a pod.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			return containerInfoMetrics(p.Status.ContainerStatuses)
+		}),
+	},
+	{
+		Name: "kube_pod_container_status_waiting",
+		Help: "Describes whether the container is currently in waiting state.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			return containerStateMetrics(p.Status.ContainerStatuses, func(s v1.ContainerStatus) float64 {
+				return metric.BoolFloat64(s.State.Waiting != nil)
+			})
+		}),
+	},
+	{
+		Name: "kube_pod_container_status_waiting_reason",
+		Help: "Describes the reason the container is currently in waiting state.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			return containerStateReasonMetrics(p.Status.ContainerStatuses, func(s v1.ContainerStatus) (string, bool) {
+				if s.State.Waiting == nil {
+					return "", false
+				}
+				return s.State.Waiting.Reason, true
+			})
+		}),
+	},
+	{
+		Name: "kube_pod_container_status_running",
+		Help: "Describes whether the container is currently in running state.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			return containerStateMetrics(p.Status.ContainerStatuses, func(s v1.ContainerStatus) float64 {
+				return metric.BoolFloat64(s.State.Running != nil)
+			})
+		}),
+	},
+	{
+		Name: "kube_pod_container_status_terminated",
+		Help: "Describes whether the container is currently in terminated state.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			return containerStateMetrics(p.Status.ContainerStatuses, func(s v1.ContainerStatus) float64 {
+				return metric.BoolFloat64(s.State.Terminated != nil)
+			})
+		}),
+	},
+	{
+		Name: "kube_pod_container_status_terminated_reason",
+		Help: "Describes the reason the container is currently in terminated state.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			return containerStateReasonMetrics(p.Status.ContainerStatuses, func(s v1.ContainerStatus) (string, bool) {
+				if s.State.Terminated == nil {
+					return "", false
+				}
+				return s.State.Terminated.Reason, true
+			})
+		}),
+	},
+	{
+		Name: "kube_pod_container_status_last_terminated_reason",
+		Help: "Describes the last reason the container was in terminated state.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			return containerStateReasonMetrics(p.Status.ContainerStatuses, func(s v1.ContainerStatus) (string, bool) {
+				if s.LastTerminationState.Terminated == nil {
+					return "", false
+				}
+				return s.LastTerminationState.Terminated.Reason, true
+			})
+		}),
+	},
+	{
+		Name: "kube_pod_container_status_ready",
+		Help: "Describes whether the containers readiness check succeeded.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			return containerStateMetrics(p.Status.ContainerStatuses, func(s v1.ContainerStatus) float64 {
+				return metric.BoolFloat64(s.Ready)
+			})
+		}),
+	},
+	{
+		Name: "kube_pod_container_status_restarts_total",
+		Help: "The number of container restarts per container.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			return containerStateMetrics(p.Status.ContainerStatuses, func(s v1.ContainerStatus) float64 {
+				return float64(s.RestartCount)
+			})
+		}),
+	},
+	{
+		Name: "kube_pod_container_resource_requests",
+		Help: "The number of requested request resource by a container.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			return containerResourceMetrics(p.Spec.Containers, func(c v1.Container) v1.ResourceList {
+				return c.Resources.Requests
+			})
+		}),
+	},
+	{
+		Name: "kube_pod_container_resource_limits",
+		Help: "The number of requested limit resource by a container.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			return containerResourceMetrics(p.Spec.Containers, func(c v1.Container) v1.ResourceList {
+				return c.Resources.Limits
+			})
+		}),
+	},
+	{
+		Name: "kube_pod_container_resource_requests_cpu_cores",
+		Help: "The number of requested cpu cores by a container.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			return containerQuantityMetrics(p.Spec.Containers, v1.ResourceCPU, func(c v1.Container) v1.ResourceList {
+				return c.Resources.Requests
+			})
+		}),
+	},
+	{
+		Name: "kube_pod_container_resource_requests_memory_bytes",
+		Help: "The number of requested memory bytes by a container.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			return containerQuantityMetrics(p.Spec.Containers, v1.ResourceMemory, func(c v1.Container) v1.ResourceList {
+				return c.Resources.Requests
+			})
+		}),
+	},
+	{
+		Name: "kube_pod_container_resource_limits_cpu_cores",
+		Help: "The limit on cpu cores to be used by a container.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			return containerQuantityMetrics(p.Spec.Containers, v1.ResourceCPU, func(c v1.Container) v1.ResourceList {
+				return c.Resources.Limits
+			})
+		}),
+	},
+	{
+		Name: "kube_pod_container_resource_limits_memory_bytes",
+		Help: "The limit on memory to be used by a container in bytes.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			return containerQuantityMetrics(p.Spec.Containers, v1.ResourceMemory, func(c v1.Container) v1.ResourceList {
+				return c.Resources.Limits
+			})
+		}),
+	},
+	{
+		Name: "kube_pod_spec_volumes_persistentvolumeclaims_info",
+		Help: "Information about persistentvolumeclaim volumes in a pod.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			ms := make([]*metric.Metric, 0, len(p.Spec.Volumes))
+			for _, v := range p.Spec.Volumes {
+				if v.PersistentVolumeClaim == nil {
+					continue
+				}
+				ms = append(ms, &metric.Metric{
+					LabelKeys:   []string{"volume", "persistentvolumeclaim"},
+					LabelValues: []string{v.Name, v.PersistentVolumeClaim.ClaimName},
+					Value:       1,
+				})
+			}
+			return ms
+		}),
+	},
+	{
+		Name: "kube_pod_spec_volumes_persistentvolumeclaims_readonly",
+		Help: "Describes whether a persistentvolumeclaim is mounted read only.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			ms := make([]*metric.Metric, 0, len(p.Spec.Volumes))
+			for _, v := range p.Spec.Volumes {
+				if v.PersistentVolumeClaim == nil {
+					continue
+				}
+				ms = append(ms, &metric.Metric{
+					LabelKeys:   []string{"volume", "persistentvolumeclaim"},
+					LabelValues: []string{v.Name, v.PersistentVolumeClaim.ClaimName},
+					Value:       metric.BoolFloat64(v.PersistentVolumeClaim.ReadOnly),
+				})
+			}
+			return ms
+		}),
+	},
+	{
+		Name: "kube_pod_init_container_info",
+		Help: "Information about an init container in a pod.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			return containerInfoMetrics(p.Status.InitContainerStatuses)
+		}),
+	},
+	{
+		Name: "kube_pod_init_container_status_waiting",
+		Help: "Describes whether the init container is currently in waiting state.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			return containerStateMetrics(p.Status.InitContainerStatuses, func(s v1.ContainerStatus) float64 {
+				return metric.BoolFloat64(s.State.Waiting != nil)
+			})
+		}),
+	},
+	{
+		Name: "kube_pod_init_container_status_waiting_reason",
+		Help: "Describes the reason the init container is currently in waiting state.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			return containerStateReasonMetrics(p.Status.InitContainerStatuses, func(s v1.ContainerStatus) (string, bool) {
+				if s.State.Waiting == nil {
+					return "", false
+				}
+				return s.State.Waiting.Reason, true
+			})
+		}),
+	},
+	{
+		Name: "kube_pod_init_container_status_running",
+		Help: "Describes whether the init container is currently in running state.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			return containerStateMetrics(p.Status.InitContainerStatuses, func(s v1.ContainerStatus) float64 {
+				return metric.BoolFloat64(s.State.Running != nil)
+			})
+		}),
+	},
+	{
+		Name: "kube_pod_init_container_status_terminated",
+		Help: "Describes whether the init container is currently in terminated state.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			return containerStateMetrics(p.Status.InitContainerStatuses, func(s v1.ContainerStatus) float64 {
+				return metric.BoolFloat64(s.State.Terminated != nil)
+			})
+		}),
+	},
+	{
+		Name: "kube_pod_init_container_status_terminated_reason",
+		Help: "Describes the reason the init container is currently in terminated state.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			return containerStateReasonMetrics(p.Status.InitContainerStatuses, func(s v1.ContainerStatus) (string, bool) {
+				if s.State.Terminated == nil {
+					return "", false
+				}
+				return s.State.Terminated.Reason, true
+			})
+		}),
+	},
+	{
+		Name: "kube_pod_init_container_status_last_terminated_reason",
+		Help: "Describes the last reason the init container was in terminated state.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			return containerStateReasonMetrics(p.Status.InitContainerStatuses, func(s v1.ContainerStatus) (string, bool) {
+				if s.LastTerminationState.Terminated == nil {
+					return "", false
+				}
+				return s.LastTerminationState.Terminated.Reason, true
+			})
+		}),
+	},
+	{
+		Name: "kube_pod_init_container_status_ready",
+		Help: "Describes whether the init containers readiness check succeeded.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			return containerStateMetrics(p.Status.InitContainerStatuses, func(s v1.ContainerStatus) float64 {
+				return metric.BoolFloat64(s.Ready)
+			})
+		}),
+	},
+	{
+		Name: "kube_pod_init_container_status_restarts_total",
+		Help: "The number of init container restarts per container.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			return containerStateMetrics(p.Status.InitContainerStatuses, func(s v1.ContainerStatus) float64 {
+				return float64(s.RestartCount)
+			})
+		}),
+	},
+	{
+		Name: "kube_pod_init_container_resource_requests",
+		Help: "The number of requested request resource by an init container.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			return containerResourceMetrics(p.Spec.InitContainers, func(c v1.Container) v1.ResourceList {
+				return c.Resources.Requests
+			})
+		}),
+	},
+	{
+		Name: "kube_pod_init_container_resource_limits",
+		Help: "The number of requested limit resource by an init container.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			return containerResourceMetrics(p.Spec.InitContainers, func(c v1.Container) v1.ResourceList {
+				return c.Resources.Limits
+			})
+		}),
+	},
+	{
+		Name: "kube_pod_init_container_resource_requests_cpu_cores",
+		Help: "The number of requested cpu cores by an init container.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			return containerQuantityMetrics(p.Spec.InitContainers, v1.ResourceCPU, func(c v1.Container) v1.ResourceList {
+				return c.Resources.Requests
+			})
+		}),
+	},
+	{
+		Name: "kube_pod_init_container_resource_requests_memory_bytes",
+		Help: "The number of requested memory bytes by an init container.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			return containerQuantityMetrics(p.Spec.InitContainers, v1.ResourceMemory, func(c v1.Container) v1.ResourceList {
+				return c.Resources.Requests
+			})
+		}),
+	},
+	{
+		Name: "kube_pod_init_container_resource_limits_cpu_cores",
+		Help: "The limit on cpu cores to be used by an init container.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			return containerQuantityMetrics(p.Spec.InitContainers, v1.ResourceCPU, func(c v1.Container) v1.ResourceList {
+				return c.Resources.Limits
+			})
+		}),
+	},
+	{
+		Name: "kube_pod_init_container_resource_limits_memory_bytes",
+		Help: "The limit on memory to be used by an init container in bytes.",
+		GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metric.Metric {
+			return containerQuantityMetrics(p.Spec.InitContainers, v1.ResourceMemory, func(c v1.Container) v1.ResourceList {
+				return c.Resources.Limits
+			})
+		}),
+	},
+}
+
+// wrapPodFunc adapts a function over a typed *v1.Pod into a
+// FamilyGenerator.GenerateFunc and attaches the namespace/pod labels every
+// series produced by a pod metric carries.
+func wrapPodFunc(f func(*v1.Pod) []*metric.Metric) func(interface{}) []*metric.Metric {
+	return func(obj interface{}) []*metric.Metric {
+		pod := obj.(*v1.Pod)
+
+		ms := f(pod)
+		for _, m := range ms {
+			m.LabelKeys = append([]string{"namespace", "pod"}, m.LabelKeys...)
+			m.LabelValues = append([]string{pod.Namespace, pod.Name}, m.LabelValues...)
+		}
+		return ms
+	}
+}
+
+// buildPodCollector wires a Collector for pods up to an informer scoped to
+// b.namespaces, additionally feeding b.joinIndex when the kube_pod_service
+// join collector is active.
+func (b *Builder) buildPodCollector() *Collector {
+	store := startInformer(b, &v1.Pod{}, "pods", cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { onPodChange(b, obj) },
+		UpdateFunc: func(_, obj interface{}) { onPodChange(b, obj) },
+		DeleteFunc: func(obj interface{}) { onPodDelete(b, obj) },
+	})
+	return NewCollector(store, podMetricFamilies, b.whiteBlackList)
+}
+
+func onPodChange(b *Builder, obj interface{}) {
+	if b.joinIndex == nil {
+		return
+	}
+	p := obj.(*v1.Pod)
+	b.joinIndex.setPod(p.Namespace, p.Name, p.Labels)
+}
+
+func onPodDelete(b *Builder, obj interface{}) {
+	if b.joinIndex == nil {
+		return
+	}
+	p, ok := obj.(*v1.Pod)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			return
+		}
+		p, ok = tombstone.Obj.(*v1.Pod)
+		if !ok {
+			return
+		}
+	}
+	b.joinIndex.deletePod(p.Namespace, p.Name)
+}
+
+func containerInfoMetrics(statuses []v1.ContainerStatus) []*metric.Metric {
+	ms := make([]*metric.Metric, 0, len(statuses))
+	for _, s := range statuses {
+		ms = append(ms, &metric.Metric{
+			LabelKeys:   []string{"container", "image", "image_id", "container_id"},
+			LabelValues: []string{s.Name, s.Image, s.ImageID, s.ContainerID},
+			Value:       1,
+		})
+	}
+	return ms
+}
+
+func containerStateMetrics(statuses []v1.ContainerStatus, value func(v1.ContainerStatus) float64) []*metric.Metric {
+	ms := make([]*metric.Metric, 0, len(statuses))
+	for _, s := range statuses {
+		ms = append(ms, &metric.Metric{
+			LabelKeys:   []string{"container"},
+			LabelValues: []string{s.Name},
+			Value:       value(s),
+		})
+	}
+	return ms
+}
+
+func containerStateReasonMetrics(statuses []v1.ContainerStatus, reason func(v1.ContainerStatus) (string, bool)) []*metric.Metric {
+	ms := make([]*metric.Metric, 0, len(statuses))
+	for _, s := range statuses {
+		r, ok := reason(s)
+		if !ok {
+			continue
+		}
+		ms = append(ms, &metric.Metric{
+			LabelKeys:   []string{"container", "reason"},
+			LabelValues: []string{s.Name, r},
+			Value:       1,
+		})
+	}
+	return ms
+}
+
+func containerResourceMetrics(containers []v1.Container, resources func(v1.Container) v1.ResourceList) []*metric.Metric {
+	var ms []*metric.Metric
+	for _, c := range containers {
+		for name, qty := range resources(c) {
+			ms = append(ms, &metric.Metric{
+				LabelKeys:   []string{"container", "resource", "unit"},
+				LabelValues: []string{c.Name, string(name), qty.Format},
+				Value:       quantityToFloat(qty),
+			})
+		}
+	}
+	return ms
+}
+
+func containerQuantityMetrics(containers []v1.Container, name v1.ResourceName, resources func(v1.Container) v1.ResourceList) []*metric.Metric {
+	var ms []*metric.Metric
+	for _, c := range containers {
+		qty, ok := resources(c)[name]
+		if !ok {
+			continue
+		}
+		ms = append(ms, &metric.Metric{
+			LabelKeys:   []string{"container"},
+			LabelValues: []string{c.Name},
+			Value:       quantityToFloat(qty),
+		})
+	}
+	return ms
+}
+
+func quantityToFloat(q resource.Quantity) float64 {
+	return float64(q.MilliValue()) / 1000
+}
+
+// ownerKindName returns the kind/name pair of the first owner reference
+// kube-state-metrics is willing to surface on kube_pod_info, falling back to
+// empty strings when the pod has none.
+func ownerKindName(p *v1.Pod) [2]string {
+	for _, o := range p.OwnerReferences {
+		return [2]string{o.Kind, o.Name}
+	}
+	return [2]string{"", ""}
+}
+
+func boolPtrString(b *bool) string {
+	if b == nil {
+		return "false"
+	}
+	if *b {
+		return "true"
+	}
+	return "false"
+}
+
+func podConditionStatus(p *v1.Pod, conditionType v1.PodConditionType) bool {
+	for _, c := range p.Status.Conditions {
+		if c.Type == conditionType {
+			return c.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"sync"
+
+	"k8s.io/client-go/tools/cache"
+
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+// podServiceMatch is a single (namespace, pod, service) pairing produced
+// once a service's selector matches a pod's labels.
+type podServiceMatch struct {
+	namespace, pod, service string
+}
+
+func podServiceMatchKeyFunc(obj interface{}) (string, error) {
+	m := obj.(*podServiceMatch)
+	return m.namespace + "/" + m.pod + "/" + m.service, nil
+}
+
+var podServiceMetricFamilies = []FamilyGenerator{
+	{
+		Name: "kube_pod_service",
+		Help: "Service selecting a pod, for joining pod and service metrics without a separate discovery layer.",
+		GenerateFunc: func(obj interface{}) []*metric.Metric {
+			m := obj.(*podServiceMatch)
+			return []*metric.Metric{
+				{
+					LabelKeys:   []string{"namespace", "pod", "service"},
+					LabelValues: []string{m.namespace, m.pod, m.service},
+					Value:       1,
+				},
+			}
+		},
+	},
+}
+
+// podServiceIndex keeps kube_pod_service's join store up to date as pod and
+// service informer events arrive. A naive O(pods*services) re-match on
+// every event is too expensive at scale, so it maintains, per namespace, an
+// inverted index from "key=value" selector pairs to the services that
+// require them; a pod event only needs to look up the few pairs its own
+// labels carry instead of scanning every service.
+type podServiceIndex struct {
+	mu sync.Mutex
+
+	// podLabels[ns][pod] is the label set last observed for pod.
+	podLabels map[string]map[string]map[string]string
+	// serviceSelectors[ns][service] is the selector last observed for service.
+	serviceSelectors map[string]map[string]map[string]string
+	// servicesBySelectorPair[ns]["key=value"] is the set of services whose
+	// selector requires that pair - the inverted index pods are probed
+	// against.
+	servicesBySelectorPair map[string]map[string]map[string]struct{}
+
+	store cache.Store
+}
+
+func newPodServiceIndex(store cache.Store) *podServiceIndex {
+	return &podServiceIndex{
+		podLabels:              map[string]map[string]map[string]string{},
+		serviceSelectors:       map[string]map[string]map[string]string{},
+		servicesBySelectorPair: map[string]map[string]map[string]struct{}{},
+		store:                  store,
+	}
+}
+
+func selectorPairs(selector map[string]string) []string {
+	pairs := make([]string, 0, len(selector))
+	for k, v := range selector {
+		pairs = append(pairs, k+"="+v)
+	}
+	return pairs
+}
+
+// selects reports whether every key/value in selector is present in labels.
+// An empty selector never matches: that's how Kubernetes Services opt out
+// of pod selection.
+func selects(selector, labels map[string]string) bool {
+	if len(selector) == 0 {
+		return false
+	}
+	for k, v := range selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func (idx *podServiceIndex) addToSelectorIndex(ns, service string, selector map[string]string) {
+	if idx.servicesBySelectorPair[ns] == nil {
+		idx.servicesBySelectorPair[ns] = map[string]map[string]struct{}{}
+	}
+	for _, pair := range selectorPairs(selector) {
+		if idx.servicesBySelectorPair[ns][pair] == nil {
+			idx.servicesBySelectorPair[ns][pair] = map[string]struct{}{}
+		}
+		idx.servicesBySelectorPair[ns][pair][service] = struct{}{}
+	}
+}
+
+func (idx *podServiceIndex) removeFromSelectorIndex(ns, service string, selector map[string]string) {
+	for _, pair := range selectorPairs(selector) {
+		delete(idx.servicesBySelectorPair[ns][pair], service)
+	}
+}
+
+// candidateServices returns every service in ns whose selector requires at
+// least one of labels' pairs - the set a pod with these labels could
+// possibly match.
+func (idx *podServiceIndex) candidateServices(ns string, labels map[string]string) map[string]struct{} {
+	candidates := map[string]struct{}{}
+	for k, v := range labels {
+		for svc := range idx.servicesBySelectorPair[ns][k+"="+v] {
+			candidates[svc] = struct{}{}
+		}
+	}
+	return candidates
+}
+
+// setPod records pod's current labels and resyncs the join store for every
+// service that could now match it, old or new.
+func (idx *podServiceIndex) setPod(ns, pod string, labels map[string]string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if idx.podLabels[ns] == nil {
+		idx.podLabels[ns] = map[string]map[string]string{}
+	}
+	old := idx.podLabels[ns][pod]
+	idx.podLabels[ns][pod] = labels
+
+	candidates := idx.candidateServices(ns, old)
+	for svc := range idx.candidateServices(ns, labels) {
+		candidates[svc] = struct{}{}
+	}
+
+	for svc := range candidates {
+		idx.syncMatch(ns, pod, svc, idx.serviceSelectors[ns][svc], labels)
+	}
+}
+
+// deletePod drops pod and any join rows it was part of.
+func (idx *podServiceIndex) deletePod(ns, pod string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	labels := idx.podLabels[ns][pod]
+	delete(idx.podLabels[ns], pod)
+
+	for svc := range idx.candidateServices(ns, labels) {
+		idx.store.Delete(&podServiceMatch{namespace: ns, pod: pod, service: svc})
+	}
+}
+
+// setService records service's current selector and resyncs the join store
+// against every pod in ns - the inverted index only narrows the
+// pod-to-service direction, so the service-to-pod direction still checks
+// the (small, namespace-scoped) pod set directly.
+func (idx *podServiceIndex) setService(ns, service string, selector map[string]string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if idx.serviceSelectors[ns] == nil {
+		idx.serviceSelectors[ns] = map[string]map[string]string{}
+	}
+	if old, ok := idx.serviceSelectors[ns][service]; ok {
+		idx.removeFromSelectorIndex(ns, service, old)
+	}
+	idx.serviceSelectors[ns][service] = selector
+	idx.addToSelectorIndex(ns, service, selector)
+
+	for pod, labels := range idx.podLabels[ns] {
+		idx.syncMatch(ns, pod, service, selector, labels)
+	}
+}
+
+// deleteService drops service and any join rows it was part of.
+func (idx *podServiceIndex) deleteService(ns, service string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	selector := idx.serviceSelectors[ns][service]
+	idx.removeFromSelectorIndex(ns, service, selector)
+	delete(idx.serviceSelectors[ns], service)
+
+	for pod := range idx.podLabels[ns] {
+		idx.store.Delete(&podServiceMatch{namespace: ns, pod: pod, service: service})
+	}
+}
+
+func (idx *podServiceIndex) syncMatch(ns, pod, service string, selector, labels map[string]string) {
+	m := &podServiceMatch{namespace: ns, pod: pod, service: service}
+	if selects(selector, labels) {
+		idx.store.Add(m)
+	} else {
+		idx.store.Delete(m)
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+
+	"k8s.io/client-go/tools/cache"
+)
+
+// matchedServices returns the set of service names store currently joins
+// pod to, by rendering podServiceMetricFamilies over it.
+func matchedServices(store cache.Store, pod string) map[string]bool {
+	services := map[string]bool{}
+	for _, family := range (&Collector{Store: store, generators: podServiceMetricFamilies}).Collect() {
+		for _, m := range family.Metrics {
+			if m.LabelValues[1] != pod {
+				continue
+			}
+			services[m.LabelValues[2]] = true
+		}
+	}
+	return services
+}
+
+func TestPodServiceIndex(t *testing.T) {
+	idx := newPodServiceIndex(cache.NewStore(podServiceMatchKeyFunc))
+
+	idx.setService("default", "svc1", map[string]string{"app": "web"})
+	idx.setPod("default", "pod1", map[string]string{"app": "web"})
+
+	if got := matchedServices(idx.store, "pod1"); !got["svc1"] {
+		t.Fatalf("expected pod1 to join svc1, got %v", got)
+	}
+
+	// A pod relabeled away from the selector should drop out of the join.
+	idx.setPod("default", "pod1", map[string]string{"app": "other"})
+	if got := matchedServices(idx.store, "pod1"); got["svc1"] {
+		t.Fatalf("expected pod1 to no longer join svc1 after relabeling, got %v", got)
+	}
+
+	// Relabeling back should restore the join.
+	idx.setPod("default", "pod1", map[string]string{"app": "web"})
+	if got := matchedServices(idx.store, "pod1"); !got["svc1"] {
+		t.Fatalf("expected pod1 to rejoin svc1, got %v", got)
+	}
+
+	// A second service with the same selector should also match.
+	idx.setService("default", "svc2", map[string]string{"app": "web"})
+	if got := matchedServices(idx.store, "pod1"); !got["svc1"] || !got["svc2"] {
+		t.Fatalf("expected pod1 to join both svc1 and svc2, got %v", got)
+	}
+
+	// Deleting a service should drop only that join row.
+	idx.deleteService("default", "svc2")
+	if got := matchedServices(idx.store, "pod1"); !got["svc1"] || got["svc2"] {
+		t.Fatalf("expected pod1 to join only svc1 after svc2 deletion, got %v", got)
+	}
+
+	// Deleting the pod should drop its remaining join rows.
+	idx.deletePod("default", "pod1")
+	if got := matchedServices(idx.store, "pod1"); len(got) != 0 {
+		t.Fatalf("expected no joins left for pod1 after deletion, got %v", got)
+	}
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+var serviceMetricFamilies = []FamilyGenerator{
+	{
+		Name: "kube_service_info",
+		Help: "Information about service.",
+		GenerateFunc: wrapServiceFunc(func(s *v1.Service) []*metric.Metric {
+			return []*metric.Metric{
+				{
+					LabelKeys: []string{"cluster_ip", "external_name", "load_balancer_ip"},
+					LabelValues: []string{
+						s.Spec.ClusterIP,
+						s.Spec.ExternalName,
+						s.Spec.LoadBalancerIP,
+					},
+					Value: 1,
+				},
+			}
+		}),
+	},
+	{
+		Name: "kube_service_created",
+		Help: "Unix creation timestamp",
+		GenerateFunc: wrapServiceFunc(func(s *v1.Service) []*metric.Metric {
+			if s.CreationTimestamp.IsZero() {
+				return nil
+			}
+			return []*metric.Metric{{Value: float64(s.CreationTimestamp.Unix())}}
+		}),
+	},
+	{
+		Name: "kube_service_spec_type",
+		Help: "Type about service.",
+		GenerateFunc: wrapServiceFunc(func(s *v1.Service) []*metric.Metric {
+			return []*metric.Metric{
+				{
+					LabelKeys:   []string{"type"},
+					LabelValues: []string{string(s.Spec.Type)},
+					Value:       1,
+				},
+			}
+		}),
+	},
+	{
+		Name: "kube_service_labels",
+		Help: "Kubernetes labels converted to Prometheus labels.",
+		GenerateFunc: wrapServiceFunc(func(s *v1.Service) []*metric.Metric {
+			keys, values := kubeLabelsToPrometheusLabels(s.Labels)
+			return []*metric.Metric{{LabelKeys: keys, LabelValues: values, Value: 1}}
+		}),
+	},
+	{
+		Name: "kube_service_spec_external_ip",
+		Help: "Service external ips. One series for each ip",
+		GenerateFunc: wrapServiceFunc(func(s *v1.Service) []*metric.Metric {
+			ms := make([]*metric.Metric, 0, len(s.Spec.ExternalIPs))
+			for _, ip := range s.Spec.ExternalIPs {
+				ms = append(ms, &metric.Metric{
+					LabelKeys:   []string{"external_ip"},
+					LabelValues: []string{ip},
+					Value:       1,
+				})
+			}
+			return ms
+		}),
+	},
+	{
+		Name: "kube_service_status_load_balancer_ingress",
+		Help: "Service load balancer ingress status",
+		GenerateFunc: wrapServiceFunc(func(s *v1.Service) []*metric.Metric {
+			ingress := s.Status.LoadBalancer.Ingress
+			ms := make([]*metric.Metric, 0, len(ingress))
+			for _, in := range ingress {
+				ms = append(ms, &metric.Metric{
+					LabelKeys:   []string{"ip", "hostname"},
+					LabelValues: []string{in.IP, in.Hostname},
+					Value:       1,
+				})
+			}
+			return ms
+		}),
+	},
+}
+
+// wrapServiceFunc adapts a function over a typed *v1.Service into a
+// FamilyGenerator.GenerateFunc and attaches the namespace/service labels
+// every series produced by a service metric carries.
+func wrapServiceFunc(f func(*v1.Service) []*metric.Metric) func(interface{}) []*metric.Metric {
+	return func(obj interface{}) []*metric.Metric {
+		svc := obj.(*v1.Service)
+
+		ms := f(svc)
+		for _, m := range ms {
+			m.LabelKeys = append([]string{"namespace", "service"}, m.LabelKeys...)
+			m.LabelValues = append([]string{svc.Namespace, svc.Name}, m.LabelValues...)
+		}
+		return ms
+	}
+}
+
+// buildServiceCollector wires a Collector for services up to an informer
+// scoped to b.namespaces, additionally feeding b.joinIndex when the
+// kube_pod_service join collector is active.
+func (b *Builder) buildServiceCollector() *Collector {
+	store := startInformer(b, &v1.Service{}, "services", cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { onServiceChange(b, obj) },
+		UpdateFunc: func(_, obj interface{}) { onServiceChange(b, obj) },
+		DeleteFunc: func(obj interface{}) { onServiceDelete(b, obj) },
+	})
+	return NewCollector(store, serviceMetricFamilies, b.whiteBlackList)
+}
+
+func onServiceChange(b *Builder, obj interface{}) {
+	if b.joinIndex == nil {
+		return
+	}
+	svc := obj.(*v1.Service)
+	b.joinIndex.setService(svc.Namespace, svc.Name, svc.Spec.Selector)
+}
+
+func onServiceDelete(b *Builder, obj interface{}) {
+	if b.joinIndex == nil {
+		return
+	}
+	svc, ok := obj.(*v1.Service)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			return
+		}
+		svc, ok = tombstone.Obj.(*v1.Service)
+		if !ok {
+			return
+		}
+	}
+	b.joinIndex.deleteService(svc.Namespace, svc.Name)
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+)
+
+// kubeLabelsToPrometheusLabels converts a Kubernetes label set into the
+// label_<key>/label value pairs every *_labels metric exposes.
+func kubeLabelsToPrometheusLabels(labels map[string]string) ([]string, []string) {
+	keys := make([]string, 0, len(labels))
+	values := make([]string, 0, len(labels))
+	for k, v := range labels {
+		keys = append(keys, "label_"+sanitizeLabelName(k))
+		values = append(values, v)
+	}
+	return keys, values
+}
+
+// sanitizeLabelName replaces characters Prometheus label names can't
+// contain with underscores.
+func sanitizeLabelName(s string) string {
+	out := make([]rune, len(s))
+	for i, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			out[i] = r
+		default:
+			out[i] = '_'
+		}
+	}
+	return string(out)
+}
+
+// coreClient returns the REST client used to list/watch core/v1 resources.
+func coreClient(b *Builder) rest.Interface {
+	return b.kubeClient.CoreV1().RESTClient()
+}
+
+// startInformer starts one controller per watched namespace for resource,
+// all of them funneling into a single shared cache.Store, and returns that
+// store. Every controller invokes handler for the Add/Update/Delete it
+// observes; pass an empty cache.ResourceEventHandlerFuncs when a collector
+// only needs the store, not the events themselves.
+//
+// cache.NewInformer can't be used directly here: it allocates its own store
+// per call, so calling it once per namespace would leave every namespace
+// but the last watching the apiserver into a store nothing ever reads.
+// Feeding a DeltaFIFO built over one shared store, per namespace, is
+// cache.NewInformer's own strategy minus that per-call store allocation.
+func startInformer(b *Builder, obj runtime.Object, resource string, handler cache.ResourceEventHandlerFuncs) cache.Store {
+	store := cache.NewStore(cache.MetaNamespaceKeyFunc)
+
+	for _, ns := range b.namespaces {
+		lw := cache.NewListWatchFromClient(coreClient(b), resource, ns, fields.Everything())
+		controller := cache.New(&cache.Config{
+			Queue:            cache.NewDeltaFIFO(cache.MetaNamespaceKeyFunc, store),
+			ListerWatcher:    lw,
+			ObjectType:       obj,
+			FullResyncPeriod: 0,
+			RetryOnError:     false,
+			Process:          processDeltas(store, handler),
+		})
+		go controller.Run(b.ctx.Done())
+	}
+
+	return store
+}
+
+// processDeltas returns the cache.Config.Process func that applies a
+// DeltaFIFO pop's deltas to store and reports them to handler - the same
+// handling cache.NewInformer does internally, reimplemented here because
+// that logic isn't exported for reuse across a shared store.
+func processDeltas(store cache.Store, handler cache.ResourceEventHandlerFuncs) func(interface{}) error {
+	return func(obj interface{}) error {
+		for _, d := range obj.(cache.Deltas) {
+			switch d.Type {
+			case cache.Sync, cache.Added, cache.Updated:
+				if old, exists, err := store.Get(d.Object); err == nil && exists {
+					if err := store.Update(d.Object); err != nil {
+						return err
+					}
+					handler.OnUpdate(old, d.Object)
+				} else {
+					if err := store.Add(d.Object); err != nil {
+						return err
+					}
+					handler.OnAdd(d.Object)
+				}
+			case cache.Deleted:
+				if err := store.Delete(d.Object); err != nil {
+					return err
+				}
+				handler.OnDelete(d.Object)
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package customresource loads the --custom-resource-config file and turns
+// each entry into what pkg/collectors needs to watch an arbitrary CRD and
+// render metrics from it: a GroupVersionResource to inform on and a set of
+// JSONPath expressions pulling label and sample values out of the objects
+// that informer observes. It knows nothing about cache.Store or
+// collectors.Collector - those belong to pkg/collectors, which wires this
+// package's output into the same collector machinery the built-in
+// resources use.
+package customresource
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// LabelMapping pulls one label's value out of a resource via JSONPath.
+type LabelMapping struct {
+	Name string `yaml:"name"`
+	Path string `yaml:"path"`
+}
+
+// MetricMapping describes one metric family to generate from a configured
+// resource: its name/help/type, the JSONPath yielding the sample value, and
+// the labels to attach to it. Type is a Prometheus/OpenMetrics metric type
+// ("gauge", "counter", ...); an empty Type means "gauge".
+type MetricMapping struct {
+	Name      string         `yaml:"name"`
+	Help      string         `yaml:"help"`
+	Type      string         `yaml:"type"`
+	ValuePath string         `yaml:"valuePath"`
+	Labels    []LabelMapping `yaml:"labels"`
+}
+
+// Resource is a single CRD to watch: the GVR to inform on and the metrics
+// to emit per object observed.
+type Resource struct {
+	Group    string          `yaml:"group"`
+	Version  string          `yaml:"version"`
+	Resource string          `yaml:"resource"`
+	Metrics  []MetricMapping `yaml:"metrics"`
+}
+
+// GroupVersionResource returns the schema.GroupVersionResource r's
+// informer should watch.
+func (r Resource) GroupVersionResource() schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: r.Group, Version: r.Version, Resource: r.Resource}
+}
+
+// Config is the parsed --custom-resource-config file: the set of CRDs to
+// watch and the metrics to derive from each.
+type Config struct {
+	Resources []Resource `yaml:"resources"`
+}
+
+// LoadConfig reads and parses the YAML file at path.
+func LoadConfig(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading custom resource config %q: %v", path, err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing custom resource config %q: %v", path, err)
+	}
+
+	return &cfg, nil
+}
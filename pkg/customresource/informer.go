@@ -0,0 +1,109 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package customresource
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+)
+
+// resourceInterface returns the dynamic.ResourceInterface to list/watch gvr
+// through: namespace-scoped when ns is set, cluster-wide (every namespace)
+// when ns is the empty string, the same convention pkg/collectors' own
+// informers use.
+func resourceInterface(client dynamic.Interface, gvr schema.GroupVersionResource, ns string) dynamic.ResourceInterface {
+	nri := client.Resource(gvr)
+	if ns == "" {
+		return nri
+	}
+	return nri.Namespace(ns)
+}
+
+// StartInformer starts one controller per namespace in namespaces for gvr,
+// all of them funneling into a single shared cache.Store, and returns that
+// store - mirroring pkg/collectors' startInformer but against a dynamic
+// client and unstructured.Unstructured objects instead of a typed one.
+//
+// cache.NewInformer can't be used directly here: it allocates its own store
+// per call, so calling it once per namespace would leave every namespace
+// but the last watching the apiserver into a store nothing ever reads.
+// Feeding a DeltaFIFO built over one shared store, per namespace, is
+// cache.NewInformer's own strategy minus that per-call store allocation.
+func StartInformer(ctx context.Context, client dynamic.Interface, gvr schema.GroupVersionResource, namespaces []string) cache.Store {
+	store := cache.NewStore(cache.MetaNamespaceKeyFunc)
+
+	for _, ns := range namespaces {
+		ri := resourceInterface(client, gvr, ns)
+		lw := &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return ri.List(options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return ri.Watch(options)
+			},
+		}
+
+		controller := cache.New(&cache.Config{
+			Queue:            cache.NewDeltaFIFO(cache.MetaNamespaceKeyFunc, store),
+			ListerWatcher:    lw,
+			ObjectType:       &unstructured.Unstructured{},
+			FullResyncPeriod: 0,
+			RetryOnError:     false,
+			Process:          processDeltas(store),
+		})
+		go controller.Run(ctx.Done())
+	}
+
+	return store
+}
+
+// processDeltas returns the cache.Config.Process func that applies a
+// DeltaFIFO pop's deltas to store - the same handling cache.NewInformer
+// does internally, reimplemented here because that logic isn't exported
+// for reuse across a shared store. No ResourceEventHandler is threaded
+// through: nothing outside the store itself currently consumes custom
+// resource add/update/delete events.
+func processDeltas(store cache.Store) func(interface{}) error {
+	return func(obj interface{}) error {
+		for _, d := range obj.(cache.Deltas) {
+			switch d.Type {
+			case cache.Sync, cache.Added, cache.Updated:
+				if _, exists, err := store.Get(d.Object); err == nil && exists {
+					if err := store.Update(d.Object); err != nil {
+						return err
+					}
+				} else {
+					if err := store.Add(d.Object); err != nil {
+						return err
+					}
+				}
+			case cache.Deleted:
+				if err := store.Delete(d.Object); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+}
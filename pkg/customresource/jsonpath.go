@@ -0,0 +1,85 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package customresource
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// CompiledJSONPath is a {.foo.bar}-style JSONPath expression parsed once so
+// it can be evaluated against every object a collector renders without
+// reparsing the expression on each of them.
+type CompiledJSONPath struct {
+	path string
+	jp   *jsonpath.JSONPath
+}
+
+// CompileJSONPath parses path for later repeated use by ExtractValue/
+// ExtractLabel.
+func CompileJSONPath(path string) (*CompiledJSONPath, error) {
+	jp := jsonpath.New(path)
+	if err := jp.Parse(path); err != nil {
+		return nil, fmt.Errorf("parsing JSONPath %q: %v", path, err)
+	}
+	return &CompiledJSONPath{path: path, jp: jp}, nil
+}
+
+// eval evaluates c against obj and renders the first matching value as a
+// string.
+func (c *CompiledJSONPath) eval(obj *unstructured.Unstructured) (string, error) {
+	results, err := c.jp.FindResults(obj.Object)
+	if err != nil {
+		return "", fmt.Errorf("evaluating JSONPath %q: %v", c.path, err)
+	}
+	if len(results) == 0 || len(results[0]) == 0 {
+		return "", fmt.Errorf("JSONPath %q matched no values", c.path)
+	}
+
+	var buf bytes.Buffer
+	if err := c.jp.PrintResults(&buf, results[0][:1]); err != nil {
+		return "", fmt.Errorf("rendering JSONPath %q: %v", c.path, err)
+	}
+
+	return buf.String(), nil
+}
+
+// ExtractValue evaluates c against obj and parses the result as a metric
+// sample value.
+func (c *CompiledJSONPath) ExtractValue(obj *unstructured.Unstructured) (float64, error) {
+	s, err := c.eval(obj)
+	if err != nil {
+		return 0, err
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("JSONPath %q value %q is not a number: %v", c.path, s, err)
+	}
+
+	return v, nil
+}
+
+// ExtractLabel evaluates c against obj and renders the result as a label
+// value.
+func (c *CompiledJSONPath) ExtractLabel(obj *unstructured.Unstructured) (string, error) {
+	return c.eval(obj)
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metric holds the lightweight types collectors use to describe the
+// series they expose, independent of how those series are eventually
+// rendered (plain text, OpenMetrics, ...).
+package metric
+
+import (
+	"io"
+	"strconv"
+)
+
+// Metric represents a single Prometheus time series: a set of label
+// key/value pairs and the sample value they identify.
+type Metric struct {
+	LabelKeys   []string
+	LabelValues []string
+	Value       float64
+}
+
+// Family groups every Metric sharing a name together with the text used in
+// its HELP comment. A Family is written even when it has no Metrics, so
+// that clients always see every series kube-state-metrics knows how to
+// produce. Type is the OpenMetrics metric type ("gauge", "counter", ...);
+// an empty Type is rendered as "gauge", the type every built-in generator
+// produces.
+type Family struct {
+	Name    string
+	Help    string
+	Type    string
+	Metrics []*Metric
+}
+
+// Write renders the family in kube-state-metrics' plain-text exposition
+// format to w: a single "# HELP" line followed by zero or more
+// "name{labels} value" sample lines.
+func (f *Family) Write(w io.Writer) {
+	io.WriteString(w, "# HELP ")
+	io.WriteString(w, f.Name)
+	io.WriteString(w, " ")
+	io.WriteString(w, f.Help)
+	io.WriteString(w, "\n")
+
+	f.writeSamples(w)
+}
+
+// WriteOpenMetrics renders the family in the OpenMetrics text format to w:
+// the "# TYPE" line OpenMetrics requires ahead of "# HELP", using f.Type
+// (defaulting to "gauge" when unset), followed by the same sample lines as
+// Write. It does not write the exposition-closing "# EOF" line, since that
+// belongs once at the end of the whole response, not after each family.
+func (f *Family) WriteOpenMetrics(w io.Writer) {
+	metricType := f.Type
+	if metricType == "" {
+		metricType = "gauge"
+	}
+
+	io.WriteString(w, "# TYPE ")
+	io.WriteString(w, f.Name)
+	io.WriteString(w, " ")
+	io.WriteString(w, metricType)
+	io.WriteString(w, "\n")
+	io.WriteString(w, "# HELP ")
+	io.WriteString(w, f.Name)
+	io.WriteString(w, " ")
+	io.WriteString(w, f.Help)
+	io.WriteString(w, "\n")
+
+	f.writeSamples(w)
+}
+
+func (f *Family) writeSamples(w io.Writer) {
+	for _, m := range f.Metrics {
+		io.WriteString(w, f.Name)
+		if len(m.LabelKeys) > 0 {
+			io.WriteString(w, "{")
+			for i, k := range m.LabelKeys {
+				if i > 0 {
+					io.WriteString(w, ",")
+				}
+				io.WriteString(w, k)
+				io.WriteString(w, `="`)
+				io.WriteString(w, m.LabelValues[i])
+				io.WriteString(w, `"`)
+			}
+			io.WriteString(w, "}")
+		}
+		io.WriteString(w, " ")
+		io.WriteString(w, strconv.FormatFloat(m.Value, 'g', -1, 64))
+		io.WriteString(w, "\n")
+	}
+}
+
+// BoolFloat64 converts a boolean condition into the 1/0 value Prometheus
+// gauges use to represent it.
+func BoolFloat64(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
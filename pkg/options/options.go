@@ -0,0 +1,144 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package options holds the flags kube-state-metrics is configured with,
+// independent of how they were parsed (pflag.FlagSet in main, or literal
+// values in tests).
+package options
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// DefaultCollectors is the set of collectors that run when
+// --collectors is left unset.
+var DefaultCollectors = CollectorSet{
+	"pods":     struct{}{},
+	"services": struct{}{},
+}
+
+// DefaultNamespaces is the set of namespaces watched when --namespaces is
+// left unset: every namespace in the cluster.
+var DefaultNamespaces = NamespaceList{NamespaceAll}
+
+// NamespaceAll matches the empty string client-go uses to mean "every
+// namespace".
+const NamespaceAll = ""
+
+// NamespaceList is a pflag.Value recording the namespaces to watch, set from
+// a comma-separated --namespaces flag.
+type NamespaceList []string
+
+// String renders l the way --namespaces expects it back.
+func (l *NamespaceList) String() string {
+	return strings.Join(*l, ",")
+}
+
+// Set replaces l with the comma-separated list of namespaces in value.
+func (l *NamespaceList) Set(value string) error {
+	*l = strings.Split(value, ",")
+	return nil
+}
+
+// Type identifies l's flag value type for pflag's usage output.
+func (l *NamespaceList) Type() string {
+	return "string"
+}
+
+// CollectorSet is a pflag.Value recording the set of enabled collectors, set
+// from a comma-separated --collectors flag.
+type CollectorSet map[string]struct{}
+
+// String renders c the way --collectors expects it back.
+func (c *CollectorSet) String() string {
+	names := make([]string, 0, len(*c))
+	for name := range *c {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// Set replaces c with the comma-separated set of collector names in value.
+func (c *CollectorSet) Set(value string) error {
+	set := CollectorSet{}
+	for _, name := range strings.Split(value, ",") {
+		set[name] = struct{}{}
+	}
+	*c = set
+	return nil
+}
+
+// Type identifies c's flag value type for pflag's usage output.
+func (c *CollectorSet) Type() string {
+	return "string"
+}
+
+// Options are the flags kube-state-metrics was started with.
+type Options struct {
+	Apiserver                string
+	Kubeconfig               string
+	Help                     bool
+	Port                     int
+	Host                     string
+	TelemetryPort            int
+	TelemetryHost            string
+	Collectors               CollectorSet
+	Namespaces               NamespaceList
+	CustomResourceConfigFile string
+
+	flags *pflag.FlagSet
+}
+
+// NewOptions returns an Options populated with kube-state-metrics' defaults.
+func NewOptions() *Options {
+	return &Options{
+		Collectors: DefaultCollectors,
+		Namespaces: DefaultNamespaces,
+	}
+}
+
+// AddFlags registers every flag kube-state-metrics accepts on o.flags.
+func (o *Options) AddFlags() {
+	o.flags = pflag.NewFlagSet("", pflag.ExitOnError)
+
+	o.flags.BoolVarP(&o.Help, "help", "h", false, "Print Help/usage.")
+	o.flags.StringVar(&o.Apiserver, "apiserver", "", "The URL of the apiserver to use as a master.")
+	o.flags.StringVar(&o.Kubeconfig, "kubeconfig", "", "Absolute path to the kubeconfig file.")
+	o.flags.IntVar(&o.Port, "port", 80, "Port to expose metrics on.")
+	o.flags.StringVar(&o.Host, "host", "0.0.0.0", "Host to expose metrics on.")
+	o.flags.IntVar(&o.TelemetryPort, "telemetry-port", 81, "Unused: no separate self-metrics endpoint is served; self metrics are exposed on /metrics.")
+	o.flags.StringVar(&o.TelemetryHost, "telemetry-host", "0.0.0.0", "Unused: no separate self-metrics endpoint is served; self metrics are exposed on /metrics.")
+	o.flags.StringVar(
+		&o.CustomResourceConfigFile, "custom-resource-config", "",
+		"Path to a YAML file describing custom resources to collect metrics for.",
+	)
+	o.flags.Var(&o.Collectors, "collectors", "Comma-separated list of collectors to use, e.g. 'pods,services'.")
+	o.flags.Var(&o.Namespaces, "namespaces", "Comma-separated list of namespaces to watch. Defaults to all namespaces.")
+}
+
+// Parse parses args into o.
+func (o *Options) Parse(args []string) error {
+	return o.flags.Parse(args)
+}
+
+// Usage prints the usage of every registered flag.
+func (o *Options) Usage() {
+	o.flags.Usage()
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package whiteblacklist implements the metric name whitelist/blacklist
+// filtering applied to generated metric names before they are exposed.
+package whiteblacklist
+
+import "fmt"
+
+// WhiteBlackList decides whether a metric name should be exposed.
+type WhiteBlackList struct {
+	list        map[string]struct{}
+	isWhiteList bool
+}
+
+// New builds a WhiteBlackList from the given sets. Exactly one of white or
+// black may be non-empty; passing both is a configuration error.
+func New(white, black map[string]struct{}) (*WhiteBlackList, error) {
+	if len(white) != 0 && len(black) != 0 {
+		return nil, fmt.Errorf("whitelist and blacklist are both set, they are mutually exclusive, only one can be set at once")
+	}
+
+	if len(white) != 0 {
+		return &WhiteBlackList{list: white, isWhiteList: true}, nil
+	}
+
+	return &WhiteBlackList{list: black, isWhiteList: false}, nil
+}
+
+// Include allows a metric name to be exposed even if it isn't covered by the
+// white/blacklist given to New.
+func (l *WhiteBlackList) Include(names []string) {
+	for _, name := range names {
+		if l.isWhiteList {
+			l.list[name] = struct{}{}
+		} else {
+			delete(l.list, name)
+		}
+	}
+}
+
+// Exclude marks a metric name as suppressed regardless of the configured
+// white/blacklist.
+func (l *WhiteBlackList) Exclude(names []string) {
+	for _, name := range names {
+		if l.isWhiteList {
+			delete(l.list, name)
+		} else {
+			l.list[name] = struct{}{}
+		}
+	}
+}
+
+// IsExcluded reports whether name should be dropped from the exposition.
+func (l *WhiteBlackList) IsExcluded(name string) bool {
+	_, found := l.list[name]
+
+	if l.isWhiteList {
+		return !found
+	}
+	return found
+}
+
+// Status returns a human readable summary of the active filtering mode, for
+// startup logging.
+func (l *WhiteBlackList) Status() string {
+	if len(l.list) == 0 {
+		return "not filtering any metrics"
+	}
+
+	mode := "blacklisting"
+	if l.isWhiteList {
+		mode = "whitelisting"
+	}
+
+	return fmt.Sprintf("%v metrics", mode)
+}